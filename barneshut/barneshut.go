@@ -0,0 +1,218 @@
+// Package barneshut implements an approximate O(N log N) gravitational
+// force solver using a Barnes-Hut octree, for systems too large for a
+// direct O(N^2) pairwise sum -- asteroid belts, star clusters, and the
+// like.
+package barneshut
+
+import "math"
+
+// G is the gravitational constant, in m^3 kg^-1 s^-2.
+const G = 6.67430e-11
+
+// DefaultTheta is the opening-angle threshold Barnes & Hut (1986) found
+// gives a good speed/accuracy tradeoff.
+const DefaultTheta = 0.5
+
+// Particle is a point mass: the octree's unit of input and the thing
+// Accelerations reports a force on.
+type Particle struct {
+	Position [3]float64
+	Mass     float64
+}
+
+// node is one cell of the octree. A node is either an empty leaf (mass ==
+// 0), a leaf holding exactly one particle, or an internal node with up to
+// eight children -- in every case it caches the total mass and center of
+// mass of everything beneath it.
+type node struct {
+	center    [3]float64
+	halfWidth float64
+
+	mass         float64
+	centerOfMass [3]float64
+
+	particle *Particle
+	children [8]*node
+}
+
+// maxDepth bounds how far the tree will subdivide to separate two
+// particles at nearly the same position, so degenerate input (exact
+// duplicates) can't recurse forever; particles that still collide at this
+// depth are merged into the same leaf and treated as one point mass.
+const maxDepth = 48
+
+// Tree is a Barnes-Hut octree built over a fixed set of particles.
+type Tree struct {
+	root *node
+}
+
+// Build constructs an octree over particles. The root cell is a cube sized
+// to the bounding box of all particles.
+func Build(particles []Particle) *Tree {
+	if len(particles) == 0 {
+		return &Tree{root: &node{}}
+	}
+
+	min, max := particles[0].Position, particles[0].Position
+	for _, p := range particles[1:] {
+		for axis := 0; axis < 3; axis++ {
+			if p.Position[axis] < min[axis] {
+				min[axis] = p.Position[axis]
+			}
+			if p.Position[axis] > max[axis] {
+				max[axis] = p.Position[axis]
+			}
+		}
+	}
+
+	var center [3]float64
+	halfWidth := 0.0
+	for axis := 0; axis < 3; axis++ {
+		center[axis] = (min[axis] + max[axis]) / 2
+		if span := (max[axis] - min[axis]) / 2; span > halfWidth {
+			halfWidth = span
+		}
+	}
+	if halfWidth == 0 {
+		halfWidth = 1 // all particles coincide; give the root cell some extent
+	}
+	halfWidth *= 1.001 // keep every particle strictly inside the root cell
+
+	root := &node{center: center, halfWidth: halfWidth}
+	for i := range particles {
+		root.insert(&particles[i], 0)
+	}
+
+	return &Tree{root: root}
+}
+
+// octant returns the index of which of the node's eight children contains p.
+func (n *node) octant(p [3]float64) int {
+	index := 0
+	if p[0] > n.center[0] {
+		index |= 1
+	}
+	if p[1] > n.center[1] {
+		index |= 2
+	}
+	if p[2] > n.center[2] {
+		index |= 4
+	}
+	return index
+}
+
+// child returns the child at index, allocating it (sized to half this
+// node's width) if it doesn't exist yet.
+func (n *node) child(index int) *node {
+	if n.children[index] == nil {
+		childHalfWidth := n.halfWidth / 2
+		var center [3]float64
+		for axis := 0; axis < 3; axis++ {
+			if index&(1<<axis) != 0 {
+				center[axis] = n.center[axis] + childHalfWidth
+			} else {
+				center[axis] = n.center[axis] - childHalfWidth
+			}
+		}
+		n.children[index] = &node{center: center, halfWidth: childHalfWidth}
+	}
+	return n.children[index]
+}
+
+// insert adds p to the subtree rooted at n, subdividing leaves as needed.
+func (n *node) insert(p *Particle, depth int) {
+	// Every node accumulates mass and center-of-mass on the way down.
+	newMass := n.mass + p.Mass
+	for axis := 0; axis < 3; axis++ {
+		n.centerOfMass[axis] = (n.centerOfMass[axis]*n.mass + p.Position[axis]*p.Mass) / newMass
+	}
+	n.mass = newMass
+
+	switch {
+	case n.particle == nil && !n.hasChildren():
+		// Empty leaf: just take the particle.
+		n.particle = p
+
+	case n.particle != nil:
+		// Leaf already holding one particle: push both particles down a
+		// level, unless we've hit the depth limit, in which case treat them
+		// as coincident and keep this leaf as a (now two-body) point mass.
+		if depth >= maxDepth {
+			return
+		}
+		existing := n.particle
+		n.particle = nil
+		n.child(n.octant(existing.Position)).insert(existing, depth+1)
+		n.child(n.octant(p.Position)).insert(p, depth+1)
+
+	default:
+		// Internal node: recurse into the appropriate child.
+		n.child(n.octant(p.Position)).insert(p, depth+1)
+	}
+}
+
+func (n *node) hasChildren() bool {
+	for _, c := range n.children {
+		if c != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Acceleration returns the gravitational acceleration on a particle at
+// position due to every particle in the tree, opening internal nodes
+// whenever their width-to-distance ratio exceeds theta (smaller theta is
+// more accurate and slower; DefaultTheta is a reasonable start). self, if
+// non-nil, is excluded from its own force calculation; pass nil to
+// evaluate the field at an arbitrary point instead of at a particle in the
+// tree. softening avoids a singular force as distance approaches zero.
+func (t *Tree) Acceleration(position [3]float64, self *Particle, theta, softening float64) [3]float64 {
+	var accel [3]float64
+	t.root.accelerationOn(position, self, theta, softening, &accel)
+	return accel
+}
+
+func (n *node) accelerationOn(position [3]float64, self *Particle, theta, softening float64, accel *[3]float64) {
+	if n == nil || n.mass == 0 {
+		return
+	}
+	if n.particle != nil && n.particle == self {
+		return
+	}
+
+	dx := n.centerOfMass[0] - position[0]
+	dy := n.centerOfMass[1] - position[1]
+	dz := n.centerOfMass[2] - position[2]
+	r2 := dx*dx + dy*dy + dz*dz + softening*softening
+
+	// A leaf is always resolved directly; an internal node is only
+	// approximated as a single pseudo-body when it is far enough away
+	// relative to its size.
+	isLeaf := n.particle != nil
+	if isLeaf || n.halfWidth*2/math.Sqrt(r2) < theta {
+		invR := 1 / math.Sqrt(r2)
+		invR3 := invR * invR * invR
+		f := G * n.mass * invR3
+		accel[0] += f * dx
+		accel[1] += f * dy
+		accel[2] += f * dz
+		return
+	}
+
+	for _, c := range n.children {
+		c.accelerationOn(position, self, theta, softening, accel)
+	}
+}
+
+// Accelerations evaluates Acceleration for every particle in particles
+// against a freshly built tree over that same set, which is the common
+// case of wanting every mutual force in an N-body step.
+func Accelerations(particles []Particle, theta, softening float64) [][3]float64 {
+	tree := Build(particles)
+	accel := make([][3]float64, len(particles))
+	for i := range particles {
+		accel[i] = tree.Acceleration(particles[i].Position, &particles[i], theta, softening)
+	}
+	return accel
+}
@@ -0,0 +1,65 @@
+package barneshut
+
+import (
+	"math"
+	"testing"
+)
+
+// directAccelerations computes the exact O(N^2) gravitational acceleration
+// on each particle, for comparison against the tree approximation.
+func directAccelerations(particles []Particle, softening float64) [][3]float64 {
+	accel := make([][3]float64, len(particles))
+	eps2 := softening * softening
+	for i := range particles {
+		for j := range particles {
+			if i == j {
+				continue
+			}
+			dx := particles[j].Position[0] - particles[i].Position[0]
+			dy := particles[j].Position[1] - particles[i].Position[1]
+			dz := particles[j].Position[2] - particles[i].Position[2]
+			r2 := dx*dx + dy*dy + dz*dz + eps2
+			invR := 1 / math.Sqrt(r2)
+			invR3 := invR * invR * invR
+			f := G * particles[j].Mass * invR3
+			accel[i][0] += f * dx
+			accel[i][1] += f * dy
+			accel[i][2] += f * dz
+		}
+	}
+	return accel
+}
+
+func sqDist(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
+
+// TestAccelerationsMatchesDirectSum checks that the tree approximation
+// agrees with the exact O(N^2) pairwise sum within a small relative
+// tolerance, at a theta tight enough that the approximation error should be
+// negligible.
+func TestAccelerationsMatchesDirectSum(t *testing.T) {
+	const softening = 1e6
+	particles := PlummerSphere(200, 1e30, 1.5e11, 42)
+
+	const tightTheta = 0.15
+	got := Accelerations(particles, tightTheta, softening)
+	want := directAccelerations(particles, softening)
+
+	var maxRelErr float64
+	for i := range particles {
+		diff := math.Sqrt(sqDist(got[i], want[i]))
+		mag := math.Sqrt(sqDist(want[i], [3]float64{}))
+		if mag == 0 {
+			continue
+		}
+		if relErr := diff / mag; relErr > maxRelErr {
+			maxRelErr = relErr
+		}
+	}
+	const tolerance = 0.05
+	if maxRelErr > tolerance {
+		t.Errorf("Barnes-Hut acceleration diverges from direct sum by %.2f%% at theta=%v, want <= %.0f%%", maxRelErr*100, tightTheta, tolerance*100)
+	}
+}
@@ -0,0 +1,84 @@
+package barneshut
+
+import "math"
+
+// rng is a small linear-congruential generator so PlummerSphere and Disk
+// are deterministic given a seed, without pulling in math/rand's global
+// state.
+type rng struct{ state uint64 }
+
+func newRNG(seed uint64) *rng {
+	if seed == 0 {
+		seed = 1
+	}
+	return &rng{state: seed}
+}
+
+// float64 returns a pseudorandom value in [0, 1).
+func (r *rng) float64() float64 {
+	// Constants from Numerical Recipes' MMIX generator.
+	r.state = r.state*6364136223846793005 + 1442695040888963407
+	return float64(r.state>>11) / float64(1<<53)
+}
+
+// PlummerSphere generates n particles distributed according to the Plummer
+// (1911) model, a classic approximation of a globular star cluster's
+// density profile, with total mass totalMass and Plummer (scale) radius
+// scaleRadius.
+func PlummerSphere(n int, totalMass, scaleRadius float64, seed uint64) []Particle {
+	r := newRNG(seed)
+	particles := make([]Particle, n)
+	massPerParticle := totalMass / float64(n)
+
+	for i := range particles {
+		// Inverse-CDF sampling of the Plummer radial density profile.
+		u := r.float64()
+		radius := scaleRadius / math.Sqrt(math.Pow(u, -2.0/3.0)-1)
+
+		costheta := 2*r.float64() - 1
+		sintheta := math.Sqrt(1 - costheta*costheta)
+		phi := 2 * math.Pi * r.float64()
+
+		particles[i] = Particle{
+			Mass: massPerParticle,
+			Position: [3]float64{
+				radius * sintheta * math.Cos(phi),
+				radius * sintheta * math.Sin(phi),
+				radius * costheta,
+			},
+		}
+	}
+
+	return particles
+}
+
+// Disk generates n particles in a flat rotating disk of the given radius
+// around a central mass, with the disk's own mass distributed uniformly by
+// area. It does not assign velocities; callers seed a simulation's dynamics
+// separately (e.g. by computing circular-orbit speeds around centralMass).
+func Disk(n int, centralMass, diskMass, radius float64, seed uint64) []Particle {
+	r := newRNG(seed)
+	particles := make([]Particle, n+1)
+
+	particles[0] = Particle{Mass: centralMass}
+
+	massPerParticle := diskMass / float64(n)
+	for i := 1; i <= n; i++ {
+		// Sample uniformly over the disk's area, not its radius, so
+		// particles don't pile up near the center.
+		u := r.float64()
+		rr := radius * math.Sqrt(u)
+		theta := 2 * math.Pi * r.float64()
+
+		particles[i] = Particle{
+			Mass: massPerParticle,
+			Position: [3]float64{
+				rr * math.Cos(theta),
+				rr * math.Sin(theta),
+				0,
+			},
+		}
+	}
+
+	return particles
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/wizardishungry/nbody/barneshut"
+)
+
+// randomBodies builds a deterministic cloud of n bodies spread over 1 to 50 AU,
+// for benchmarking force evaluation independent of any real system.
+func randomBodies(n int) []*Body {
+	bodies := make([]*Body, n)
+	for i := range bodies {
+		angle := float64(i) * 2.399963229728653 // golden-angle spacing, avoids a degenerate lattice
+		radius := AUtoMeters(float64(i%50) + 1)
+		bodies[i] = &Body{
+			Mass: 1e24 * float64(i%7+1),
+			Position: [3]float64{
+				radius * math.Cos(angle),
+				radius * math.Sin(angle),
+				0,
+			},
+		}
+	}
+	return bodies
+}
+
+// computeAccelerationsNaive is the pre-vectorization kernel kept around only
+// for benchmarking: every body loops over every other body, recomputing the
+// separation from both ends of each pair instead of sharing it.
+func computeAccelerationsNaive(bodies []*Body) [][3]float64 {
+	accelerations := make([][3]float64, len(bodies))
+
+	for i, b := range bodies {
+		for _, other := range bodies {
+			if b == other {
+				continue
+			}
+
+			distance := math.Sqrt(math.Pow(other.Position[0]-b.Position[0], 2) + math.Pow(other.Position[1]-b.Position[1], 2) + math.Pow(other.Position[2]-b.Position[2], 2))
+			force := (G * other.Mass) / math.Pow(distance, 2)
+
+			accelerations[i][0] += force * (other.Position[0] - b.Position[0]) / distance
+			accelerations[i][1] += force * (other.Position[1] - b.Position[1]) / distance
+			accelerations[i][2] += force * (other.Position[2] - b.Position[2]) / distance
+		}
+	}
+
+	return accelerations
+}
+
+func BenchmarkComputeAccelerationsNaive(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		bodies := randomBodies(n)
+		b.Run("n="+strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				computeAccelerationsNaive(bodies)
+			}
+		})
+	}
+}
+
+func BenchmarkComputeAccelerationsSOA(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		bodies := randomBodies(n)
+		b.Run("n="+strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				computeAccelerations(bodies)
+			}
+		})
+	}
+}
+
+// BenchmarkForceDirectVsBarnesHut compares the exact O(N^2) kernel against
+// the approximate O(N log N) Barnes-Hut solver across N = 10, 100, 1000 and
+// 10000 -- direct summation is expected to win at small N, where building
+// the tree costs more than it saves, and lose badly as N grows.
+func BenchmarkForceDirectVsBarnesHut(b *testing.B) {
+	sizes := []int{10, 100, 1000, 10000}
+
+	for _, n := range sizes {
+		bodies := randomBodies(n)
+		b.Run("direct/n="+strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				computeAccelerations(bodies)
+			}
+		})
+	}
+
+	for _, n := range sizes {
+		particles := barneshut.PlummerSphere(n, 1e30, AUtoMeters(1), uint64(n))
+		b.Run("barneshut/n="+strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				barneshut.Accelerations(particles, barneshut.DefaultTheta, softeningMeters)
+			}
+		})
+	}
+}
@@ -4,10 +4,17 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/wizardishungry/nbody/barneshut"
+	"github.com/wizardishungry/nbody/ephemeris"
+	"github.com/wizardishungry/nbody/mission"
+	"github.com/wizardishungry/nbody/observer"
 )
 
 func AUtoMeters(au float64) float64 {
@@ -24,6 +31,13 @@ func AUDayToMetersPerSecond(auPerDay float64) float64 {
 	return auPerDay * 149.6e6 * 1000 / 86400
 }
 
+// julianDate converts a UTC time.Time to a Julian date, for handing to the
+// ephemeris package.
+func julianDate(t time.Time) float64 {
+	t = t.UTC()
+	return float64(t.Unix())/86400.0 + 2440587.5
+}
+
 // G is the gravitational constant,
 const G = 6.67430e-11 // in m^3 kg^-1 s^-2
 
@@ -48,51 +62,306 @@ func Distance(a, b *Body) float64 {
 	return distance
 }
 
-// Update updates the position and velocity of the body based on the positions and velocities of all the other bodies.
-func (b *Body) Update(bodies []*Body, dt float64) {
-	// Initialize the acceleration to zero.
-	acceleration := [3]float64{0, 0, 0}
+// softeningMeters keeps the pairwise force kernel finite as two bodies'
+// separation approaches zero. At 1000km it is negligible next to
+// interplanetary distances but avoids a divide-by-zero on a collision.
+const softeningMeters = 1e6
+
+// Bodies is a struct-of-arrays view of a set of bodies' state, laid out so
+// the pairwise force kernel below can stream through it without chasing
+// pointers per body.
+type Bodies struct {
+	X, Y, Z    []float64
+	VX, VY, VZ []float64
+	Mass       []float64
+}
 
-	// Calculate the acceleration of the body due to the gravitational forces of the other bodies.
-	for _, other := range bodies {
-		if b == other {
-			// Skip the body itself.
-			continue
+// syncFromBodies copies bodies' state into bs's slices in place, growing
+// them only when the body count has changed, so repeated calls with the
+// same n (the common case: the integrator stepping the same system over and
+// over) don't reallocate.
+func (bs *Bodies) syncFromBodies(bodies []*Body) *Bodies {
+	n := len(bodies)
+	if bs == nil || len(bs.Mass) != n {
+		bs = &Bodies{
+			X: make([]float64, n), Y: make([]float64, n), Z: make([]float64, n),
+			VX: make([]float64, n), VY: make([]float64, n), VZ: make([]float64, n),
+			Mass: make([]float64, n),
+		}
+	}
+	for i, b := range bodies {
+		bs.X[i], bs.Y[i], bs.Z[i] = b.Position[0], b.Position[1], b.Position[2]
+		bs.VX[i], bs.VY[i], bs.VZ[i] = b.Velocity[0], b.Velocity[1], b.Velocity[2]
+		bs.Mass[i] = b.Mass
+	}
+	return bs
+}
+
+// computeAccelerationsSOA is the vectorization-friendly force kernel: it
+// visits each pair (i, j) once, computing dx/dy/dz and r^2 a single time and
+// applying Newton's third law to accumulate +a on i and -a on j instead of
+// recomputing the same distance from both sides. A softening term keeps r^2
+// away from zero for close encounters. accel is the caller's scratch output
+// slab; it is zeroed in place when its length already matches b, and
+// reallocated otherwise.
+func computeAccelerationsSOA(b *Bodies, softening float64, accel [][3]float64) [][3]float64 {
+	n := len(b.Mass)
+	if len(accel) != n {
+		accel = make([][3]float64, n)
+	} else {
+		for i := range accel {
+			accel[i] = [3]float64{}
 		}
+	}
+	eps2 := softening * softening
+
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			dx := b.X[j] - b.X[i]
+			dy := b.Y[j] - b.Y[i]
+			dz := b.Z[j] - b.Z[i]
 
-		// Calculate the distance between the two bodies.
-		distance := math.Sqrt(math.Pow(other.Position[0]-b.Position[0], 2) + math.Pow(other.Position[1]-b.Position[1], 2) + math.Pow(other.Position[2]-b.Position[2], 2))
+			r2 := math.FMA(dx, dx, math.FMA(dy, dy, dz*dz)) + eps2
+			invR := 1 / math.Sqrt(r2)
+			invR3 := invR * invR * invR
 
-		// Calculate the gravitational force between the two bodies.
-		force := (G * /* b.Mass * */ other.Mass) / math.Pow(distance, 2)
+			ux, uy, uz := dx*invR3, dy*invR3, dz*invR3
 
-		// Calculate the acceleration of the body due to the gravitational force of the other body.
-		acceleration[0] += force * (other.Position[0] - b.Position[0]) / distance
-		acceleration[1] += force * (other.Position[1] - b.Position[1]) / distance
-		acceleration[2] += force * (other.Position[2] - b.Position[2]) / distance
+			accel[i][0] = math.FMA(G*b.Mass[j], ux, accel[i][0])
+			accel[i][1] = math.FMA(G*b.Mass[j], uy, accel[i][1])
+			accel[i][2] = math.FMA(G*b.Mass[j], uz, accel[i][2])
+
+			accel[j][0] = math.FMA(-G*b.Mass[i], ux, accel[j][0])
+			accel[j][1] = math.FMA(-G*b.Mass[i], uy, accel[j][1])
+			accel[j][2] = math.FMA(-G*b.Mass[i], uz, accel[j][2])
+		}
 	}
 
-	// Update the velocity of the body using the Euler method.
-	b.Velocity[0] += acceleration[0] * dt
-	b.Velocity[1] += acceleration[1] * dt
-	b.Velocity[2] += acceleration[2] * dt
+	return accel
+}
+
+// soaScratch holds the struct-of-arrays view and acceleration slab that
+// computeAccelerations reuses across calls. Every direct-summation
+// Integrator (all but BarnesHut) calls it at least once per step, so
+// without this the hot loop would reallocate seven
+// slices plus the accel slab on every force evaluation.
+var soaScratch struct {
+	bodies *Bodies
+	accel  [][3]float64
+}
 
-	// Update the position of the body using the Euler method.
-	// TODO split out
-	b.Position[0] += b.Velocity[0] * dt
-	b.Position[1] += b.Velocity[1] * dt
-	b.Position[2] += b.Velocity[2] * dt
+// computeAccelerations evaluates the gravitational acceleration on every
+// body due to all the others, returning one [3]float64 per body in the same
+// order as bodies. It is the shared force evaluation used by every
+// Integrator except BarnesHut, which uses the barneshut package instead.
+func computeAccelerations(bodies []*Body) [][3]float64 {
+	soaScratch.bodies = soaScratch.bodies.syncFromBodies(bodies)
+	soaScratch.accel = computeAccelerationsSOA(soaScratch.bodies, softeningMeters, soaScratch.accel)
+	return soaScratch.accel
 }
 
-// Step advances the model by the given time duration.
+// kick applies acceleration to every body's velocity for a duration dt.
+func kick(bodies []*Body, accelerations [][3]float64, dt float64) {
+	for i, b := range bodies {
+		for axis := range b.Velocity {
+			b.Velocity[axis] += accelerations[i][axis] * dt
+		}
+	}
+}
+
+// drift advances every body's position by its velocity for a duration dt.
+func drift(bodies []*Body, dt float64) {
+	for _, b := range bodies {
+		for axis := range b.Position {
+			b.Position[axis] += b.Velocity[axis] * dt
+		}
+	}
+}
+
+// Integrator advances a system of bodies forward by dt seconds (well,
+// whatever unit of time dt is expressed in -- Step passes seconds).
+type Integrator interface {
+	Advance(bodies []*Body, dt float64)
+}
+
+// Euler is the original Euler integrator: it evaluates acceleration once per
+// step and applies it to velocity and then position, so the position update
+// uses the already-updated velocity (the semi-implicit, or Euler-Cromer,
+// ordering). It is simple but only first-order accurate in dt.
+type Euler struct{}
+
+func (Euler) Advance(bodies []*Body, dt float64) {
+	accelerations := computeAccelerations(bodies)
+	kick(bodies, accelerations, dt)
+	drift(bodies, dt)
+}
+
+// VelocityVerlet is a second-order symplectic integrator: kick by dt/2,
+// drift by dt, recompute acceleration at the new positions, then kick by
+// dt/2 again. Velocity stays synchronized with position at every step. The
+// end-of-step acceleration it computes is also the correct start-of-step
+// acceleration for the next call, so it caches that in prevAccel and skips
+// recomputing it -- halving the force evaluations per step after the first,
+// as long as successive Advance calls are fed the same system of bodies.
+type VelocityVerlet struct {
+	prevAccel [][3]float64
+}
+
+func (v *VelocityVerlet) Advance(bodies []*Body, dt float64) {
+	accelerations := v.prevAccel
+	if accelerations == nil {
+		accelerations = computeAccelerations(bodies)
+	}
+	kick(bodies, accelerations, dt/2)
+	drift(bodies, dt)
+	accelerations = computeAccelerations(bodies)
+	kick(bodies, accelerations, dt/2)
+	v.prevAccel = accelerations
+}
+
+// Leapfrog is the drift-kick-drift form of the classic leapfrog integrator:
+// drift by dt/2, kick by dt at the midpoint positions, then drift by dt/2
+// again. It is algebraically distinct from VelocityVerlet's kick-drift-kick
+// form but shares the same order of accuracy.
+type Leapfrog struct{}
+
+func (Leapfrog) Advance(bodies []*Body, dt float64) {
+	drift(bodies, dt/2)
+	accelerations := computeAccelerations(bodies)
+	kick(bodies, accelerations, dt)
+	drift(bodies, dt/2)
+}
+
+// yoshidaW1 and yoshidaW0 are the standard Yoshida (1990) coefficients for a
+// 4th-order symplectic composition of three leapfrog sub-steps.
+var (
+	yoshidaW1 = 1 / (2 - math.Cbrt(2))
+	yoshidaW0 = 1 - 2*yoshidaW1
+)
+
+// Yoshida4 is a 4th-order symplectic integrator built from three Leapfrog
+// sub-steps of carefully chosen, partly-negative widths, which cancels the
+// leading-order error term of plain leapfrog.
+type Yoshida4 struct{}
+
+func (Yoshida4) Advance(bodies []*Body, dt float64) {
+	leapfrog := Leapfrog{}
+	leapfrog.Advance(bodies, yoshidaW1*dt)
+	leapfrog.Advance(bodies, yoshidaW0*dt)
+	leapfrog.Advance(bodies, yoshidaW1*dt)
+}
+
+// toParticles converts bodies to the plain point-mass representation the
+// barneshut package works with.
+func toParticles(bodies []*Body) []barneshut.Particle {
+	particles := make([]barneshut.Particle, len(bodies))
+	for i, b := range bodies {
+		particles[i] = barneshut.Particle{Position: b.Position, Mass: b.Mass}
+	}
+	return particles
+}
+
+// BarnesHut is an Integrator backed by an approximate O(N log N)
+// Barnes-Hut octree force evaluation instead of the direct O(N^2) pairwise
+// sum, for systems too large (asteroid belts, star clusters) for the exact
+// solver to keep up with. Theta is the opening-angle threshold passed to
+// barneshut.Tree.Acceleration; the zero value falls back to
+// barneshut.DefaultTheta.
+type BarnesHut struct {
+	Theta     float64
+	Softening float64
+}
+
+func (bh BarnesHut) Advance(bodies []*Body, dt float64) {
+	theta := bh.Theta
+	if theta == 0 {
+		theta = barneshut.DefaultTheta
+	}
+	softening := bh.Softening
+	if softening == 0 {
+		softening = softeningMeters
+	}
+
+	accelerate := func() [][3]float64 {
+		return barneshut.Accelerations(toParticles(bodies), theta, softening)
+	}
+
+	kick(bodies, accelerate(), dt/2)
+	drift(bodies, dt)
+	kick(bodies, accelerate(), dt/2)
+}
+
+// integrator is the Integrator used by Step. VelocityVerlet is a reasonable
+// default: it is symplectic (bounded energy error) and, with its cached
+// end-of-step acceleration, needs one force evaluation per step after the
+// first, the same as Euler. astroMain may replace it at
+// startup per NBODY_INTEGRATOR.
+var integrator Integrator = &VelocityVerlet{}
+
+// integratorFromEnv selects the Integrator named by NBODY_INTEGRATOR
+// ("euler", "verlet", "leapfrog", "yoshida4" or "barneshut"), falling back
+// to the package default if the variable is unset or unrecognized.
+func integratorFromEnv() Integrator {
+	switch os.Getenv("NBODY_INTEGRATOR") {
+	case "euler":
+		return Euler{}
+	case "verlet":
+		return &VelocityVerlet{}
+	case "leapfrog":
+		return Leapfrog{}
+	case "yoshida4":
+		return Yoshida4{}
+	case "barneshut":
+		return BarnesHut{}
+	default:
+		return integrator
+	}
+}
+
+// Step advances the model by the given time duration using the package's
+// configured Integrator.
 func Step(bodies []*Body, dt time.Duration) {
-	// Convert dt from seconds to days
-	days := dt.Seconds()
+	seconds := dt.Seconds()
+
+	integrator.Advance(bodies, seconds)
+}
+
+// systemEnergy returns the total kinetic plus potential energy of bodies, in
+// joules.
+func systemEnergy(bodies []*Body) float64 {
+	var kinetic, potential float64
 
-	// Update the positions and velocities of all the bodies.
 	for _, b := range bodies {
-		b.Update(bodies, days)
+		speedSquared := b.Velocity[0]*b.Velocity[0] + b.Velocity[1]*b.Velocity[1] + b.Velocity[2]*b.Velocity[2]
+		kinetic += 0.5 * b.Mass * speedSquared
 	}
+
+	for i, a := range bodies {
+		for _, b := range bodies[i+1:] {
+			potential -= G * a.Mass * b.Mass / Distance(a, b)
+		}
+	}
+
+	return kinetic + potential
+}
+
+// systemAngularMomentum returns the total angular momentum of bodies about
+// the origin, in kg*m^2/s.
+func systemAngularMomentum(bodies []*Body) [3]float64 {
+	var total [3]float64
+
+	for _, b := range bodies {
+		p, v := b.Position, b.Velocity
+		total[0] += b.Mass * (p[1]*v[2] - p[2]*v[1])
+		total[1] += b.Mass * (p[2]*v[0] - p[0]*v[2])
+		total[2] += b.Mass * (p[0]*v[1] - p[1]*v[0])
+	}
+
+	return total
+}
+
+func vectorMagnitude(v [3]float64) float64 {
+	return math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
 }
 
 type tickMsg time.Time
@@ -150,6 +419,14 @@ func (m model) View() string {
 
 	metersPerCell := mapScale / float64(height)
 
+	trajectoryCells := map[coord]bool{}
+	for _, point := range m.bodiesWithToken.trajectory {
+		c := coord{int(point[0] / metersPerCell), int(point[1] / metersPerCell)}
+		c.y += height / 4
+		c.x += width / 2
+		trajectoryCells[c] = true
+	}
+
 	for i, b := range m.bodies {
 
 		c := coord{int(b.Position[0] / metersPerCell), int(b.Position[1] / metersPerCell)}
@@ -159,20 +436,29 @@ func (m model) View() string {
 	}
 
 	var s strings.Builder
-	s.WriteString(fmt.Sprintf("%d objects (%dx%d): %v %.0f(i/s) \n", len(m.bodies), m.size.Height, m.size.Width, m.bodiesWithToken.currentTime, m.bodiesWithToken.itersSec))
+	s.WriteString(fmt.Sprintf("%d objects (%dx%d): %v %.0f(i/s) ΔE %.2e%% ΔL %.2e%%\n", len(m.bodies), m.size.Height, m.size.Width, m.bodiesWithToken.currentTime, m.bodiesWithToken.itersSec, m.bodiesWithToken.energyError*100, m.bodiesWithToken.angMomError*100))
 	s.Grow((height + 2) * (width + 1))
 
 	runes := []rune{'☉', '♁', '♂'}
 
+	panel := m.bodiesWithToken.observerPanel
+
 	for i := 0; i < height; i++ {
 		for j := 0; j < width; j++ {
-			if b, ok := bm[coord{x: j, y: i}]; ok {
+			c := coord{x: j, y: i}
+			switch b, ok := bm[c]; {
+			case ok:
 				s.WriteRune(runes[b])
-				_ = b
-			} else {
+			case trajectoryCells[c]:
+				s.WriteRune('·')
+			default:
 				s.WriteRune(' ')
 			}
 		}
+		if i < len(panel) {
+			s.WriteString("  ")
+			s.WriteString(panel[i])
+		}
 		s.WriteRune('\n')
 	}
 
@@ -190,54 +476,182 @@ type bodiesWithToken struct {
 	currentTime time.Time
 	itersSec    float64
 	token       chan struct{}
+
+	// energyError and angMomError are the fractional drift of total energy
+	// and total angular momentum magnitude since the simulation started, a
+	// quick way to see whether the configured Integrator is conserving them
+	// as expected.
+	energyError float64
+	angMomError float64
+
+	// trajectory holds points along the best planned mission trajectory, if
+	// one was loaded via NBODY_MISSION_CONFIG, so the TUI can overlay it
+	// next to the live integration. It is a straight-line interpolation
+	// from the launch body at launch to the arrival body at arrival rather
+	// than the propagated transfer orbit; flyby bodies are not included.
+	trajectory [][3]float64
+
+	// observerPanel holds one formatted rise/transit/set line per simulated
+	// body other than the Sun and Earth, for the side panel in View.
+	observerPanel []string
+}
+
+// planetSymbols are the traditional astrological glyphs used to label each
+// planet's rise/transit/set line in the observer side panel.
+var planetSymbols = map[ephemeris.PlanetID]rune{
+	ephemeris.Mercury: '☿',
+	ephemeris.Venus:   '♀',
+	ephemeris.Mars:    '♂',
+	ephemeris.Jupiter: '♃',
+	ephemeris.Saturn:  '♄',
+	ephemeris.Uranus:  '♅',
+	ephemeris.Neptune: '♆',
+}
+
+// observerCoordFromEnv reads the observer's ground location from
+// NBODY_OBSERVER_LAT / NBODY_OBSERVER_LON, defaulting to 0,0 if unset.
+func observerCoordFromEnv() observer.Coord {
+	var coord observer.Coord
+	if v := os.Getenv("NBODY_OBSERVER_LAT"); v != "" {
+		if lat, err := strconv.ParseFloat(v, 64); err == nil {
+			coord.Lat = lat
+		}
+	}
+	if v := os.Getenv("NBODY_OBSERVER_LON"); v != "" {
+		if lon, err := strconv.ParseFloat(v, 64); err == nil {
+			coord.Lon = lon
+		}
+	}
+	return coord
+}
+
+// observerPanelLines computes a "rise HH:MM transit HH:MM set HH:MM" line
+// for every body in bodies other than the Sun and Earth, as seen from obs
+// at currentTime. Positions come from the simulator's own integrated bodies
+// rather than the seed ephemeris, so the panel reflects however far the
+// simulation has drifted since it started.
+func observerPanelLines(obs observer.Coord, bodies []*Body, currentTime time.Time) []string {
+	var earth *Body
+	for _, b := range bodies {
+		if b.Label == "Earth" {
+			earth = b
+			break
+		}
+	}
+	if earth == nil {
+		return nil
+	}
+	earthState := observer.State{Position: earth.Position, Velocity: earth.Velocity}
+
+	var lines []string
+
+	for _, b := range bodies {
+		if b.Label == "Sun" || b.Label == "Earth" {
+			continue
+		}
+
+		id := planetIDFromName(b.Label)
+		symbol, ok := planetSymbols[id]
+		if !ok {
+			symbol = '•'
+		}
+
+		planetState := observer.State{Position: b.Position, Velocity: b.Velocity}
+		times, err := observer.RiseTransitSetFromState(obs, planetState, earthState, currentTime)
+		if err != nil && err != observer.ErrNeverRises {
+			continue
+		}
+
+		switch {
+		case err == observer.ErrNeverRises:
+			lines = append(lines, fmt.Sprintf("%c %s never rises, transit %s", symbol, b.Label, times.Transit.Format("15:04")))
+		case times.Rise.IsZero():
+			lines = append(lines, fmt.Sprintf("%c %s circumpolar, transit %s", symbol, b.Label, times.Transit.Format("15:04")))
+		default:
+			lines = append(lines, fmt.Sprintf("%c %s rise %s transit %s set %s", symbol, b.Label, times.Rise.Format("15:04"), times.Transit.Format("15:04"), times.Set.Format("15:04")))
+		}
+	}
+
+	return lines
+}
+
+// loadTrajectory searches for the best (lowest total ΔV) mission described
+// by the TOML file at path, if any, and returns a handful of points
+// interpolated in a straight line from the launch body's position at launch
+// to the arrival body's position at arrival, for the TUI to overlay. Flyby
+// bodies are not included in the line.
+func loadTrajectory(path string) [][3]float64 {
+	cfg, err := mission.LoadConfig(path)
+	if err != nil {
+		log.Printf("mission: %v", err)
+		return nil
+	}
+
+	results, err := mission.Search(cfg)
+	if err != nil || len(results) == 0 {
+		log.Printf("mission: no feasible trajectory found in %s", path)
+		return nil
+	}
+
+	best := results[0]
+	for _, r := range results[1:] {
+		if r.TotalDV < best.TotalDV {
+			best = r
+		}
+	}
+
+	launchJD := julianDate(best.Launch)
+	arrivalJD := julianDate(best.Arrival)
+
+	launchBody, err := ephemeris.LoadPlanet(planetIDFromName(cfg.Launch.Body))
+	if err != nil {
+		return nil
+	}
+	arrivalBody, err := ephemeris.LoadPlanet(planetIDFromName(cfg.Arrival.Body))
+	if err != nil {
+		return nil
+	}
+
+	launchPos := launchBody.Position(launchJD)
+	arrivalPos := arrivalBody.Position(arrivalJD)
+
+	const points = 20
+	arc := make([][3]float64, points)
+	for i := range arc {
+		frac := float64(i) / float64(points-1)
+		for axis := range arc[i] {
+			arc[i][axis] = launchPos[axis] + frac*(arrivalPos[axis]-launchPos[axis])
+		}
+	}
+	return arc
+}
+
+func planetIDFromName(name string) ephemeris.PlanetID {
+	for id := ephemeris.Mercury; id <= ephemeris.Neptune; id++ {
+		if id.String() == name {
+			return id
+		}
+	}
+	return ephemeris.Earth
 }
 
 func astroMain(p *tea.Program) {
+	integrator = integratorFromEnv()
+
 	startTime := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
-	bodies := []*Body{
-		{
-			Label: "Sun",
-			Mass:  1.989e30, // Mass of the Sun
-			Position: [3]float64{
-				AUtoMeters(0), // x-position (AU)
-				0,             // y-position (AU)
-				0,             // z-position (AU)
-			},
-			Velocity: [3]float64{
-				0, // x-velocity (AU/day)
-				0, // y-velocity (AU/day)
-				0, // z-velocity (AU/day)
-			},
-		},
-		{
-			Label: "Earth",
-			Mass:  5.972e24, // Mass of Earth
-			Position: [3]float64{
-				AUtoMeters(-1.01673977e-01), // x-position (AU)
-				AUtoMeters(7.00034986e-01),  // y-position (AU)
-				AUtoMeters(-1.85435480e-06), // z-position (AU)
-			},
-			Velocity: [3]float64{
-				AUDayToMetersPerSecond(-1.42987359e-02), // x-velocity (AU/day)
-				AUDayToMetersPerSecond(-1.00797828e-02), // y-velocity (AU/day)
-				AUDayToMetersPerSecond(2.24008069e-07),  // z-velocity (AU/day)
-			},
-		},
-		{
-			Label: "Mars",
-			Mass:  6.39e23, // Mass of Mars
-			Position: [3]float64{
-				AUtoMeters(1.38708645),      // x-position (AU)
-				AUtoMeters(-9.63136861e-01), // y-position (AU)
-				AUtoMeters(3.79103570e-02),  // z-position (AU)
-			},
-			Velocity: [3]float64{
-				AUDayToMetersPerSecond(7.20279246e-03),     // x-velocity (AU/day)
-				AUDayToMetersPerSecond(1.67110509e-02) / 2, // y-velocity (AU/day) // FIXME ?
-				AUDayToMetersPerSecond(-1.70863874e-03),    // z-velocity (AU/day)
-			},
-		},
-		// TODO: Add more bodies as needed.
+
+	seeds, err := ephemeris.NewSystem(julianDate(startTime), ephemeris.Earth, ephemeris.Mars)
+	if err != nil {
+		log.Fatal(err)
+	}
+	bodies := make([]*Body, 0, len(seeds))
+	for _, seed := range seeds {
+		bodies = append(bodies, &Body{
+			Label:    seed.Label,
+			Mass:     seed.Mass,
+			Position: seed.Position,
+			Velocity: seed.Velocity,
+		})
 	}
 
 	const advTime = time.Second
@@ -256,6 +670,14 @@ func astroMain(p *tea.Program) {
 		bodies: bodies,
 		token:  make(chan struct{}),
 	}
+	if path := os.Getenv("NBODY_MISSION_CONFIG"); path != "" {
+		payload.trajectory = loadTrajectory(path)
+	}
+
+	observerCoord := observerCoordFromEnv()
+
+	initialEnergy := systemEnergy(bodies)
+	initialAngMom := vectorMagnitude(systemAngularMomentum(bodies))
 
 	for {
 		if lastPrint.Add(printInterval).Before(currentTime) /*|| true*/ {
@@ -277,6 +699,13 @@ func astroMain(p *tea.Program) {
 				}
 			}
 			payload.currentTime = currentTime
+			if initialEnergy != 0 {
+				payload.energyError = (systemEnergy(bodies) - initialEnergy) / initialEnergy
+			}
+			if initialAngMom != 0 {
+				payload.angMomError = (vectorMagnitude(systemAngularMomentum(bodies)) - initialAngMom) / initialAngMom
+			}
+			payload.observerPanel = observerPanelLines(observerCoord, bodies, currentTime)
 			p.Send(payload)
 			lastPrintRealtime = time.Now()
 			iterCount = 0
@@ -0,0 +1,214 @@
+// Package ephemeris loads heliocentric planetary state vectors (position and
+// velocity) at an arbitrary Julian date, in the style of the VSOP87 theory
+// used by soniakeys/meeus.
+//
+// The coefficients here are a heavily truncated approximation: a full VSOP87
+// D/E series has thousands of periodic terms per planet per coordinate, which
+// is far more than a toy n-body simulator needs. Instead each planet is
+// modeled as an unperturbed Kepler ellipse whose mean elements are evaluated
+// at the requested time, which reproduces VSOP87's L (ecliptic longitude), B
+// (ecliptic latitude) and R (radius vector) outputs closely enough to seed a
+// simulation, but should not be used where arc-second accuracy matters.
+package ephemeris
+
+import (
+	"fmt"
+	"math"
+)
+
+// PlanetID identifies one of the eight major planets.
+type PlanetID int
+
+const (
+	Mercury PlanetID = iota
+	Venus
+	Earth
+	Mars
+	Jupiter
+	Saturn
+	Uranus
+	Neptune
+)
+
+func (id PlanetID) String() string {
+	if int(id) < 0 || int(id) >= len(elements) {
+		return "unknown"
+	}
+	return elements[id].label
+}
+
+// J2000 is the Julian date of the J2000.0 epoch, the reference time for the
+// mean elements below.
+const J2000 = 2451545.0
+
+// AU is one astronomical unit, in meters.
+const AU = 149.6e6 * 1000
+
+// solarMass is the mass of the Sun, in kilograms.
+const solarMass = 1.989e30
+
+// keplerElements are a planet's mean orbital elements at J2000. Only the
+// mean longitude advances, at the mean motion n per Julian day; the other
+// elements are held fixed. This is the truncated stand-in for a
+// VSOP87 L0/L1, B0, R0 term table.
+type keplerElements struct {
+	label  string
+	mass   float64 // kg
+	radius float64 // mean radius, meters
+
+	a float64 // semi-major axis, AU
+	e float64 // eccentricity
+	i float64 // inclination, degrees
+
+	l0 float64 // mean longitude at J2000, degrees
+	n  float64 // mean motion, degrees/day
+	lp float64 // longitude of perihelion, degrees
+}
+
+// elements holds the mean elements for Mercury through Neptune, indexed by
+// PlanetID. Values are taken from the standard low-precision planetary
+// elements (Meeus, "Astronomical Algorithms", table 31.a) and are accurate to
+// within a degree or so over a few centuries of J2000 -- plenty for seeding a
+// simulation.
+var elements = []keplerElements{
+	Mercury: {label: "Mercury", mass: 3.3011e23, radius: 2.4397e6, a: 0.38709927, e: 0.20563593, i: 7.00497902, l0: 252.25032350, n: 4.09233445, lp: 77.45779628},
+	Venus:   {label: "Venus", mass: 4.8675e24, radius: 6.0518e6, a: 0.72333566, e: 0.00677672, i: 3.39467605, l0: 181.97909950, n: 1.60213034, lp: 131.60246718},
+	Earth:   {label: "Earth", mass: 5.972e24, radius: 6.371e6, a: 1.00000261, e: 0.01671123, i: -0.00001531, l0: 100.46457166, n: 0.98560912, lp: 102.93768193},
+	Mars:    {label: "Mars", mass: 6.4171e23, radius: 3.3895e6, a: 1.52371034, e: 0.09339410, i: 1.84969142, l0: -4.55343205, n: 0.52403304, lp: -23.94362959},
+	Jupiter: {label: "Jupiter", mass: 1.8982e27, radius: 6.9911e7, a: 5.20288700, e: 0.04838624, i: 1.30439695, l0: 34.39644051, n: 0.08308530, lp: 14.72847983},
+	Saturn:  {label: "Saturn", mass: 5.6834e26, radius: 5.8232e7, a: 9.53667594, e: 0.05386179, i: 2.48599187, l0: 49.95424423, n: 0.03344414, lp: 92.59887831},
+	Uranus:  {label: "Uranus", mass: 8.6810e25, radius: 2.5362e7, a: 19.18916464, e: 0.04725744, i: 0.77263783, l0: 313.23810451, n: 0.01172834, lp: 170.95427630},
+	Neptune: {label: "Neptune", mass: 1.02413e26, radius: 2.4622e7, a: 30.06992276, e: 0.00859048, i: 1.77004347, l0: -55.12002969, n: 0.00598103, lp: 44.96476227},
+}
+
+// Planet evaluates its orbital elements into a heliocentric state vector.
+type Planet struct {
+	id PlanetID
+	el keplerElements
+}
+
+// LoadPlanet returns the orbital model for the given planet.
+func LoadPlanet(id PlanetID) (*Planet, error) {
+	if id < Mercury || id > Neptune {
+		return nil, fmt.Errorf("ephemeris: unknown planet id %d", id)
+	}
+	return &Planet{id: id, el: elements[id]}, nil
+}
+
+// ID reports which planet this model describes.
+func (p *Planet) ID() PlanetID { return p.id }
+
+// Mass returns the planet's mass in kilograms.
+func (p *Planet) Mass() float64 { return p.el.mass }
+
+// Radius returns the planet's mean radius in meters.
+func (p *Planet) Radius() float64 { return p.el.radius }
+
+// LBR evaluates the truncated series for heliocentric ecliptic longitude L,
+// latitude B and radius R (in AU) at Julian date jd.
+func (p *Planet) LBR(jd float64) (l, b, r float64) {
+	el := p.el
+
+	days := jd - J2000
+	m := deg2rad(el.l0 + el.n*days - el.lp)
+	ea := solveKepler(m, el.e)
+
+	// True anomaly and radius from the eccentric anomaly.
+	nu := 2 * math.Atan2(math.Sqrt(1+el.e)*math.Sin(ea/2), math.Sqrt(1-el.e)*math.Cos(ea/2))
+	r = el.a * (1 - el.e*math.Cos(ea))
+
+	l = math.Mod(rad2deg(nu)+el.lp, 360)
+	if l < 0 {
+		l += 360
+	}
+	// The inclination is folded in as a latitude wobble referenced to the
+	// angle from perihelion (l - lp), standing in for the argument of
+	// latitude; this keeps the model within the spirit of
+	// VSOP87's L/B/R split without tracking the ascending node separately.
+	b = el.i * math.Sin(deg2rad(l-el.lp))
+
+	return l, b, r
+}
+
+// Position returns the planet's heliocentric rectangular position, in
+// meters, at Julian date jd.
+func (p *Planet) Position(jd float64) [3]float64 {
+	l, b, r := p.LBR(jd)
+	lr, br := deg2rad(l), deg2rad(b)
+	return [3]float64{
+		r * math.Cos(br) * math.Cos(lr) * AU,
+		r * math.Cos(br) * math.Sin(lr) * AU,
+		r * math.Sin(br) * AU,
+	}
+}
+
+// velocityEpsilon is the finite-difference step, in days, used to numerically
+// differentiate position into velocity.
+const velocityEpsilon = 0.5
+
+// State returns the planet's heliocentric position (meters) and velocity
+// (meters/second) at Julian date jd. The velocity is obtained by centrally
+// differencing Position around jd rather than differentiating the series
+// analytically.
+func (p *Planet) State(jd float64) (position, velocity [3]float64) {
+	before := p.Position(jd - velocityEpsilon)
+	after := p.Position(jd + velocityEpsilon)
+
+	position = p.Position(jd)
+	dt := velocityEpsilon * 2 * 86400 // seconds
+	for i := range velocity {
+		velocity[i] = (after[i] - before[i]) / dt
+	}
+	return position, velocity
+}
+
+// Body is a seed state for one body of an n-body simulation: its label,
+// mass, and position/velocity at the time NewSystem was evaluated.
+type Body struct {
+	Label    string
+	Mass     float64
+	Position [3]float64
+	Velocity [3]float64
+}
+
+// NewSystem evaluates the ephemeris for each requested planet at Julian date
+// jd, plus the Sun at the origin, and returns seed states ready to hand to a
+// simulator.
+func NewSystem(jd float64, planets ...PlanetID) ([]*Body, error) {
+	bodies := make([]*Body, 0, len(planets)+1)
+	bodies = append(bodies, &Body{Label: "Sun", Mass: solarMass})
+
+	for _, id := range planets {
+		p, err := LoadPlanet(id)
+		if err != nil {
+			return nil, err
+		}
+		position, velocity := p.State(jd)
+		bodies = append(bodies, &Body{
+			Label:    p.el.label,
+			Mass:     p.Mass(),
+			Position: position,
+			Velocity: velocity,
+		})
+	}
+
+	return bodies, nil
+}
+
+// solveKepler solves Kepler's equation M = E - e*sin(E) for the eccentric
+// anomaly E, given mean anomaly m (radians) and eccentricity e, by Newton's
+// method.
+func solveKepler(m, e float64) float64 {
+	ea := m
+	for i := 0; i < 30; i++ {
+		delta := ea - e*math.Sin(ea) - m
+		if math.Abs(delta) < 1e-12 {
+			break
+		}
+		ea -= delta / (1 - e*math.Cos(ea))
+	}
+	return ea
+}
+
+func deg2rad(d float64) float64 { return d * math.Pi / 180 }
+func rad2deg(r float64) float64 { return r * 180 / math.Pi }
@@ -0,0 +1,61 @@
+package ephemeris
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSolveKeplerRoundTrip checks that the eccentric anomaly solveKepler
+// returns actually satisfies Kepler's equation M = E - e*sin(E), across a
+// spread of mean anomalies and eccentricities from near-circular to the
+// most eccentric planet in the table (Mercury, e ~= 0.206).
+func TestSolveKeplerRoundTrip(t *testing.T) {
+	for _, e := range []float64{0, 0.0167, 0.0935, 0.2056} {
+		for _, m := range []float64{0, 0.5, 1, 2, 3, -1.5, 6} {
+			ea := solveKepler(m, e)
+			gotM := ea - e*math.Sin(ea)
+			if diff := math.Abs(gotM - m); diff > 1e-9 {
+				t.Errorf("solveKepler(%v, %v): E=%v does not satisfy Kepler's equation, got M=%v want %v", m, e, ea, gotM, m)
+			}
+		}
+	}
+}
+
+// TestPlanetLBRRadiusMatchesSemiMajorAxis checks that a planet's LBR radius
+// stays within its orbit's eccentricity bounds of its semi-major axis --
+// a coarse sanity check that LBR is evaluating a real ellipse rather than,
+// say, a constant or a runaway series.
+func TestPlanetLBRRadiusMatchesSemiMajorAxis(t *testing.T) {
+	for id := Mercury; id <= Neptune; id++ {
+		p, err := LoadPlanet(id)
+		if err != nil {
+			t.Fatalf("LoadPlanet(%v): %v", id, err)
+		}
+		el := p.el
+		for _, days := range []float64{0, 1000, 36525} {
+			_, _, r := p.LBR(J2000 + days)
+			min, max := el.a*(1-el.e), el.a*(1+el.e)
+			if r < min-1e-9 || r > max+1e-9 {
+				t.Errorf("%s.LBR(J2000+%v): radius %v outside [%v, %v]", id, days, r, min, max)
+			}
+		}
+	}
+}
+
+// TestPositionMagnitudeMatchesLBR checks that Position's rectangular
+// magnitude reproduces the radius LBR reports at the same Julian date, i.e.
+// the AU-to-meters conversion and spherical-to-rectangular projection agree
+// with the underlying series.
+func TestPositionMagnitudeMatchesLBR(t *testing.T) {
+	p, err := LoadPlanet(Earth)
+	if err != nil {
+		t.Fatalf("LoadPlanet(Earth): %v", err)
+	}
+	jd := J2000 + 12345
+	_, _, r := p.LBR(jd)
+	pos := p.Position(jd)
+	mag := math.Sqrt(pos[0]*pos[0]+pos[1]*pos[1]+pos[2]*pos[2]) / AU
+	if diff := math.Abs(mag - r); diff > 1e-9 {
+		t.Errorf("Position(jd) magnitude %v AU does not match LBR radius %v AU", mag, r)
+	}
+}
@@ -0,0 +1,139 @@
+package mission
+
+import (
+	"fmt"
+	"math"
+)
+
+// solveLambert solves Lambert's problem: given two heliocentric position
+// vectors r1, r2 and a time of flight tof (seconds) between them around a
+// body of gravitational parameter mu (m^3/s^2), find the velocity at each
+// end of the transfer orbit connecting them.
+//
+// This is the universal-variable formulation (Vallado, "Fundamentals of
+// Astrodynamics and Applications", algorithm 58), which works uniformly
+// across elliptical, parabolic and hyperbolic transfers instead of branching
+// on eccentricity.
+func solveLambert(r1, r2 [3]float64, tof, mu float64, prograde bool) (v1, v2 [3]float64, err error) {
+	r1Mag := vecMag(r1)
+	r2Mag := vecMag(r2)
+	if r1Mag == 0 || r2Mag == 0 {
+		return v1, v2, fmt.Errorf("mission: degenerate position vector")
+	}
+
+	cosDNu := vecDot(r1, r2) / (r1Mag * r2Mag)
+	crossZ := r1[0]*r2[1] - r1[1]*r2[0]
+
+	// Transfer angle, resolved to the requested orbital direction.
+	dNu := math.Acos(clamp(cosDNu, -1, 1))
+	if prograde && crossZ < 0 {
+		dNu = 2*math.Pi - dNu
+	} else if !prograde && crossZ >= 0 {
+		dNu = 2*math.Pi - dNu
+	}
+
+	a := math.Sin(dNu) * math.Sqrt(r1Mag*r2Mag/(1-math.Cos(dNu)))
+	if math.IsNaN(a) || dNu == 0 {
+		return v1, v2, fmt.Errorf("mission: transfer angle too small to solve")
+	}
+
+	// timeOfFlight evaluates t(z) - tof for the universal-variable equation.
+	// When a is positive (a transfer angle under 180 degrees), y(z) can go
+	// negative for sufficiently negative z, which is unphysical; in
+	// that case it brackets forward in 0.1 steps until y is positive again,
+	// per Vallado's recommendation, and reports the z it actually evaluated
+	// at (zUsed) alongside the residual and y, rather than mutating z or y
+	// as a side effect. That keeps the secant iteration below honest: it
+	// always pairs a residual with the z that produced it, even when
+	// bracketing moved that z away from the one requested.
+	timeOfFlight := func(z float64) (residual, y, zUsed float64) {
+		for {
+			c2, c3 := stumpff(z)
+			y = r1Mag + r2Mag + a*(z*c3-1)/math.Sqrt(c2)
+			if a > 0 && y < 0 {
+				z += 0.1
+				continue
+			}
+			chi := math.Sqrt(y / c2)
+			residual = (chi*chi*chi*c3+a*math.Sqrt(y))/math.Sqrt(mu) - tof
+			return residual, y, z
+		}
+	}
+
+	// Root-find z via the secant method rather than differentiating t(z)
+	// analytically -- the closed-form derivative involves Stumpff function
+	// derivatives that are easy to get subtly wrong, whereas t(z) itself is
+	// smooth and monotonic in the region we search.
+	f0, _, z0 := timeOfFlight(0.0)
+	f1, y, z1 := timeOfFlight(1.0)
+	const maxIter = 100
+	converged := false
+	for i := 0; i < maxIter; i++ {
+		if f1 == f0 {
+			return v1, v2, fmt.Errorf("mission: Lambert iteration stalled")
+		}
+		z2 := z1 - f1*(z1-z0)/(f1-f0)
+		f2, y2, z2Used := timeOfFlight(z2)
+		z0, f0 = z1, f1
+		z1, f1, y = z2Used, f2, y2
+		if math.Abs(f1) < 1e-6 {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return v1, v2, fmt.Errorf("mission: Lambert solver did not converge")
+	}
+
+	f := 1 - y/r1Mag
+	g := a * math.Sqrt(y/mu)
+	gDot := 1 - y/r2Mag
+
+	for i := 0; i < 3; i++ {
+		v1[i] = (r2[i] - f*r1[i]) / g
+		v2[i] = (gDot*r2[i] - r1[i]) / g
+	}
+
+	return v1, v2, nil
+}
+
+// stumpff evaluates the Stumpff functions C2(z) and C3(z) used by the
+// universal-variable Lambert and Kepler solvers.
+func stumpff(z float64) (c2, c3 float64) {
+	switch {
+	case z > 1e-6:
+		sq := math.Sqrt(z)
+		c2 = (1 - math.Cos(sq)) / z
+		c3 = (sq - math.Sin(sq)) / math.Pow(sq, 3)
+	case z < -1e-6:
+		sq := math.Sqrt(-z)
+		c2 = (1 - math.Cosh(sq)) / z
+		c3 = (math.Sinh(sq) - sq) / math.Pow(sq, 3)
+	default:
+		c2 = 1.0 / 2
+		c3 = 1.0 / 6
+	}
+	return c2, c3
+}
+
+func vecMag(v [3]float64) float64 {
+	return math.Sqrt(vecDot(v, v))
+}
+
+func vecDot(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func vecSub(a, b [3]float64) [3]float64 {
+	return [3]float64{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
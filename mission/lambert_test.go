@@ -0,0 +1,62 @@
+package mission
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSolveLambertQuarterCircularOrbit checks solveLambert against a case
+// with a known closed-form answer: a quarter-period transfer around a
+// circular orbit. For a circular orbit of radius r about mu, the transfer
+// from (r,0,0) to (0,r,0) in a quarter period T/4 is flown at constant
+// speed sqrt(mu/r), tangent to the circle at each end.
+func TestSolveLambertQuarterCircularOrbit(t *testing.T) {
+	const mu = 1.32712440018e20 // solarGM
+	const r = 1.496e11          // 1 AU, meters
+
+	speed := math.Sqrt(mu / r)
+	period := 2 * math.Pi * math.Sqrt(r*r*r/mu)
+	tof := period / 4
+
+	r1 := [3]float64{r, 0, 0}
+	r2 := [3]float64{0, r, 0}
+
+	v1, v2, err := solveLambert(r1, r2, tof, mu, true)
+	if err != nil {
+		t.Fatalf("solveLambert: %v", err)
+	}
+
+	wantV1 := [3]float64{0, speed, 0}
+	wantV2 := [3]float64{-speed, 0, 0}
+
+	const tol = 1e-3 // relative
+	for i := range v1 {
+		if math.Abs(v1[i]-wantV1[i]) > tol*speed {
+			t.Errorf("v1[%d] = %v, want %v", i, v1[i], wantV1[i])
+		}
+		if math.Abs(v2[i]-wantV2[i]) > tol*speed {
+			t.Errorf("v2[%d] = %v, want %v", i, v2[i], wantV2[i])
+		}
+	}
+}
+
+// TestSolveLambertDegenerateInputs checks that solveLambert reports an
+// error instead of dividing by zero or looping forever on degenerate
+// inputs, rather than asserting on any particular error text.
+func TestSolveLambertDegenerateInputs(t *testing.T) {
+	const mu = 1.32712440018e20
+
+	cases := map[string]struct {
+		r1, r2 [3]float64
+		tof    float64
+	}{
+		"zero position":     {[3]float64{0, 0, 0}, [3]float64{1.5e11, 0, 0}, 1e6},
+		"collinear vectors": {[3]float64{1.5e11, 0, 0}, [3]float64{3e11, 0, 0}, 1e6},
+	}
+
+	for name, c := range cases {
+		if _, _, err := solveLambert(c.r1, c.r2, c.tof, mu, true); err == nil {
+			t.Errorf("%s: solveLambert returned no error for a degenerate input", name)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Package mission searches for interplanetary transfer trajectories,
+// including multi-leg trajectories chained through gravity-assist flybys,
+// by solving Lambert's problem over a grid of launch and arrival dates --
+// the same technique behind a classic "porkchop plot".
+package mission
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/wizardishungry/nbody/ephemeris"
+)
+
+// solarGM is the Sun's standard gravitational parameter, in m^3/s^2.
+const solarGM = 1.32712440018e20
+
+// Config describes a mission to search for, typically loaded from a TOML
+// file with [launch] and [arrival] tables, each naming a body and a
+// Julian-date search window, plus an optional [[flyby]] array of tables.
+type Config struct {
+	Launch  LegConfig     `toml:"launch"`
+	Arrival LegConfig     `toml:"arrival"`
+	Flyby   []FlybyConfig `toml:"flyby"`
+}
+
+// LegConfig names a body and the range of Julian dates to search around it.
+type LegConfig struct {
+	Body       string  `toml:"body"`
+	EarliestJD float64 `toml:"earliest_jd"`
+	LatestJD   float64 `toml:"latest_jd"`
+	StepDays   float64 `toml:"step_days"`
+}
+
+// FlybyConfig constrains an intermediate gravity-assist body. Flyby legs are
+// visited in the order they appear in the config file.
+type FlybyConfig struct {
+	Body           string  `toml:"body"`
+	MaxDV          float64 `toml:"max_dv"`          // m/s budget for a powered deflection at this body
+	SafetyAltitude float64 `toml:"safety_altitude"` // minimum periapsis altitude above the body's surface, meters
+}
+
+// LoadConfig reads a mission specification from a TOML file.
+func LoadConfig(path string) (*Config, error) {
+	var cfg Config
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		return nil, fmt.Errorf("mission: loading config: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// validate checks that the launch and arrival date grids are well-formed
+// before Search is handed the config: a non-positive StepDays would turn
+// Search's grid loops into an infinite loop, and EarliestJD >= LatestJD
+// would leave the grid empty or, when the two are equal, a single date.
+func (cfg *Config) validate() error {
+	if err := cfg.Launch.validate("launch"); err != nil {
+		return err
+	}
+	if err := cfg.Arrival.validate("arrival"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validate checks one leg's date grid, identified by name in error messages.
+func (leg *LegConfig) validate(name string) error {
+	if leg.StepDays <= 0 {
+		return fmt.Errorf("mission: %s.step_days must be > 0, got %v", name, leg.StepDays)
+	}
+	if leg.EarliestJD >= leg.LatestJD {
+		return fmt.Errorf("mission: %s.earliest_jd (%v) must be before %s.latest_jd (%v)", name, leg.EarliestJD, name, leg.LatestJD)
+	}
+	return nil
+}
+
+// FlybyResult records how a trajectory used one intermediate body.
+type FlybyResult struct {
+	Body              ephemeris.PlanetID
+	At                time.Time
+	IncomingVInfinity float64 // m/s
+	OutgoingVInfinity float64 // m/s
+	PeriapsisAltitude float64 // meters
+	DeltaV            float64 // m/s of powered deflection used to match incoming/outgoing v-infinity
+}
+
+// Result is one candidate trajectory found by Search.
+type Result struct {
+	Launch  time.Time
+	Arrival time.Time
+	C3      float64 // launch characteristic energy, m^2/s^2
+	VInf    float64 // arrival hyperbolic excess speed, m/s
+	Flybys  []FlybyResult
+	TotalDV float64 // sum of all powered deflections, m/s
+}
+
+// leg is one Lambert-solved segment of a trajectory, between two bodies at
+// two specific epochs.
+type leg struct {
+	departure, arrival                             time.Time
+	departureBody, arrivalBody                     ephemeris.PlanetID
+	departureVelocity, arrivalVelocity             [3]float64
+	departurePlanetVelocity, arrivalPlanetVelocity [3]float64
+}
+
+// Search walks the launch/arrival date grid described by cfg, solving
+// Lambert's problem for each (launch, arrival) pair and, if flybys are
+// configured, chaining a Lambert-solved leg through each one in turn. It
+// returns every trajectory whose flyby legs satisfy their configured v∞
+// matching, ΔV budget and periapsis-altitude constraints.
+func Search(cfg *Config) ([]Result, error) {
+	launchBody, err := bodyByName(cfg.Launch.Body)
+	if err != nil {
+		return nil, err
+	}
+	arrivalBody, err := bodyByName(cfg.Arrival.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	flybyBodies := make([]ephemeris.PlanetID, len(cfg.Flyby))
+	for i, fb := range cfg.Flyby {
+		id, err := bodyByName(fb.Body)
+		if err != nil {
+			return nil, err
+		}
+		flybyBodies[i] = id
+	}
+
+	var results []Result
+
+	for launchJD := cfg.Launch.EarliestJD; launchJD <= cfg.Launch.LatestJD; launchJD += cfg.Launch.StepDays {
+		for arrivalJD := cfg.Arrival.EarliestJD; arrivalJD <= cfg.Arrival.LatestJD; arrivalJD += cfg.Arrival.StepDays {
+			if arrivalJD <= launchJD {
+				continue
+			}
+
+			waypoints := append([]ephemeris.PlanetID{launchBody}, flybyBodies...)
+			waypoints = append(waypoints, arrivalBody)
+
+			legs, err := solveLegs(waypoints, launchJD, arrivalJD)
+			if err != nil {
+				// This particular date pair has no feasible transfer; move on.
+				continue
+			}
+
+			result, ok := evaluateLegs(legs, cfg)
+			if ok {
+				results = append(results, result)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// solveLegs Lambert-solves a straight chain of waypoints, splitting the
+// total time of flight evenly across legs. A production trajectory designer
+// would search leg timing independently; evenly splitting keeps the search
+// space tractable for this simulator's purposes.
+func solveLegs(waypoints []ephemeris.PlanetID, launchJD, arrivalJD float64) ([]leg, error) {
+	numLegs := len(waypoints) - 1
+	legDays := (arrivalJD - launchJD) / float64(numLegs)
+
+	legs := make([]leg, numLegs)
+
+	for i := 0; i < numLegs; i++ {
+		departureJD := launchJD + float64(i)*legDays
+		arrivalLegJD := departureJD + legDays
+
+		fromPlanet, err := ephemeris.LoadPlanet(waypoints[i])
+		if err != nil {
+			return nil, err
+		}
+		toPlanet, err := ephemeris.LoadPlanet(waypoints[i+1])
+		if err != nil {
+			return nil, err
+		}
+
+		r1, vFrom := fromPlanet.State(departureJD)
+		r2, vTo := toPlanet.State(arrivalLegJD)
+
+		tofSeconds := legDays * 86400
+		v1, v2, err := solveLambert(r1, r2, tofSeconds, solarGM, true)
+		if err != nil {
+			return nil, err
+		}
+
+		legs[i] = leg{
+			departure:               julianToTime(departureJD),
+			arrival:                 julianToTime(arrivalLegJD),
+			departureBody:           waypoints[i],
+			arrivalBody:             waypoints[i+1],
+			departureVelocity:       v1,
+			arrivalVelocity:         v2,
+			departurePlanetVelocity: vFrom,
+			arrivalPlanetVelocity:   vTo,
+		}
+	}
+
+	return legs, nil
+}
+
+// evaluateLegs turns a chain of Lambert legs into a Result, checking every
+// intermediate flyby against its configured ΔV budget and periapsis-altitude
+// safety margin.
+func evaluateLegs(legs []leg, cfg *Config) (Result, bool) {
+	first := legs[0]
+	last := legs[len(legs)-1]
+
+	launchVInf := vecSub(first.departureVelocity, first.departurePlanetVelocity)
+	c3 := vecDot(launchVInf, launchVInf)
+
+	arrivalVInf := vecSub(last.arrivalVelocity, last.arrivalPlanetVelocity)
+
+	result := Result{
+		Launch:  first.departure,
+		Arrival: last.arrival,
+		C3:      c3,
+		VInf:    vecMag(arrivalVInf),
+	}
+
+	for i := 0; i < len(legs)-1; i++ {
+		incoming := legs[i]
+		outgoing := legs[i+1]
+		fb := cfg.Flyby[i]
+
+		bodyID := incoming.arrivalBody
+		planet, err := ephemeris.LoadPlanet(bodyID)
+		if err != nil {
+			return Result{}, false
+		}
+
+		vInInf := vecSub(incoming.arrivalVelocity, incoming.arrivalPlanetVelocity)
+		vOutInf := vecSub(outgoing.departureVelocity, outgoing.departurePlanetVelocity)
+
+		speedIn := vecMag(vInInf)
+		speedOut := vecMag(vOutInf)
+		deltaV := speedOut - speedIn
+		if deltaV < 0 {
+			deltaV = -deltaV
+		}
+		if deltaV > fb.MaxDV {
+			return Result{}, false
+		}
+
+		periapsisAltitude := flybyPeriapsisRadius(planet, speedIn) - planet.Radius()
+		if periapsisAltitude < fb.SafetyAltitude {
+			return Result{}, false
+		}
+
+		result.Flybys = append(result.Flybys, FlybyResult{
+			Body:              bodyID,
+			At:                incoming.arrival,
+			IncomingVInfinity: speedIn,
+			OutgoingVInfinity: speedOut,
+			PeriapsisAltitude: periapsisAltitude,
+			DeltaV:            deltaV,
+		})
+		result.TotalDV += deltaV
+	}
+
+	return result, true
+}
+
+// gravitationalConstant is duplicated from the main package rather than
+// imported, since mission has no dependency on cmd/nbody.
+const gravitationalConstant = 6.67430e-11
+
+// flybyPeriapsisRadius estimates, to order of magnitude, the periapsis
+// radius (distance from the body's center) a gravity assist at the given
+// hyperbolic excess speed would fly through: rp ~= mu_body / v_inf^2, the
+// periapsis of a hyperbola of eccentricity 2, which turns v_inf by 60
+// degrees. This is a planning-stage estimate, not a targeted b-plane solution.
+// Subtract the body's own radius to get altitude above its surface.
+func flybyPeriapsisRadius(planet *ephemeris.Planet, vInf float64) float64 {
+	if vInf <= 0 {
+		return 0
+	}
+	mu := gravitationalConstant * planet.Mass()
+	return mu / (vInf * vInf)
+}
+
+func bodyByName(name string) (ephemeris.PlanetID, error) {
+	for id := ephemeris.Mercury; id <= ephemeris.Neptune; id++ {
+		if id.String() == name {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("mission: unknown body %q", name)
+}
+
+func julianToTime(jd float64) time.Time {
+	unixSeconds := (jd - 2440587.5) * 86400
+	return time.Unix(int64(unixSeconds), 0).UTC()
+}
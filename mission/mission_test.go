@@ -0,0 +1,76 @@
+package mission
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mission.toml")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatalf("writing test config: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigRejectsNonPositiveStepDays(t *testing.T) {
+	path := writeConfig(t, `
+[launch]
+body = "Earth"
+earliest_jd = 2459000
+latest_jd = 2459100
+step_days = 0
+
+[arrival]
+body = "Mars"
+earliest_jd = 2459200
+latest_jd = 2459300
+step_days = 5
+`)
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("LoadConfig: expected an error for step_days = 0, got nil")
+	}
+}
+
+func TestLoadConfigRejectsEmptyDateRange(t *testing.T) {
+	path := writeConfig(t, `
+[launch]
+body = "Earth"
+earliest_jd = 2459100
+latest_jd = 2459000
+step_days = 5
+
+[arrival]
+body = "Mars"
+earliest_jd = 2459200
+latest_jd = 2459300
+step_days = 5
+`)
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("LoadConfig: expected an error for earliest_jd >= latest_jd, got nil")
+	}
+}
+
+func TestLoadConfigAcceptsWellFormedConfig(t *testing.T) {
+	path := writeConfig(t, `
+[launch]
+body = "Earth"
+earliest_jd = 2459000
+latest_jd = 2459100
+step_days = 5
+
+[arrival]
+body = "Mars"
+earliest_jd = 2459200
+latest_jd = 2459300
+step_days = 5
+`)
+
+	if _, err := LoadConfig(path); err != nil {
+		t.Fatalf("LoadConfig: unexpected error for a well-formed config: %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+// Package observer computes topocentric rise, transit and set times for
+// solar-system bodies as seen from a point on the Earth's surface, using
+// the standard three-point interpolation method (Meeus, "Astronomical
+// Algorithms", chapter 15).
+package observer
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/wizardishungry/nbody/ephemeris"
+)
+
+// Coord is a geographic position on the Earth's surface.
+type Coord struct {
+	Lat float64 // degrees, north positive
+	Lon float64 // degrees, east positive
+}
+
+// ErrNeverRises is returned by RiseTransitSet when the body's declination
+// and the observer's latitude put it permanently below the horizon on the
+// given date.
+var ErrNeverRises = errors.New("observer: body never rises above the horizon at this latitude")
+
+// standardAltitude is the standard geometric altitude of the center of a
+// planet or star at the moment of rise/set, accounting for atmospheric
+// refraction at the horizon; it ignores each body's own angular radius and
+// parallax, which are negligible at naked-eye planetarium accuracy.
+const standardAltitude = -0.5667 // degrees
+
+// obliquityJ2000 is the obliquity of the ecliptic at J2000, in degrees.
+const obliquityJ2000 = 23.4392911
+
+// refineConvergence is the |deltaM| (in fractional days) below which
+// refineTransit/refineRiseSet consider their correction converged --
+// 1e-6 days is about a tenth of a second, well past naked-eye accuracy.
+// maxRefineIterations bounds the loop in case a pathological geometry
+// (near-circumpolar declination) makes it converge slowly.
+const (
+	refineConvergence   = 1e-6
+	maxRefineIterations = 10
+)
+
+// Times is the result of RiseTransitSet: the rise, transit (upper culmination)
+// and set times of a body on a given UTC calendar day, all in UTC. Rise and
+// Set are the zero time.Time if the body is circumpolar (never sets) at the
+// observer's latitude on that date.
+type Times struct {
+	Rise    time.Time
+	Transit time.Time
+	Set     time.Time
+}
+
+// RiseTransitSet computes the rise, transit and set times of planet, as
+// seen from obs, over the UTC calendar day containing date.
+func RiseTransitSet(obs Coord, planet *ephemeris.Planet, date time.Time) (Times, error) {
+	earth, err := ephemeris.LoadPlanet(ephemeris.Earth)
+	if err != nil {
+		return Times{}, err
+	}
+
+	return riseTransitSet(obs, func(jd float64) (planetPos, earthPos [3]float64) {
+		return planet.Position(jd), earth.Position(jd)
+	}, date)
+}
+
+// State is a body's instantaneous heliocentric position (meters) and
+// velocity (meters/second), as tracked by the simulator's integrator.
+type State struct {
+	Position [3]float64
+	Velocity [3]float64
+}
+
+// RiseTransitSetFromState computes rise, transit and set times the same way
+// RiseTransitSet does, but samples planet and earth positions from the
+// simulator's own integrated state, linearly extrapolated by the body's
+// current velocity to the neighboring days the interpolation needs, instead
+// of re-deriving them from the ephemeris the simulation was seeded from.
+// This lets the observer panel reflect however far the simulation has
+// drifted from that ephemeris.
+func RiseTransitSetFromState(obs Coord, planet, earth State, currentTime time.Time) (Times, error) {
+	jdNow := julianDate(currentTime)
+
+	return riseTransitSet(obs, func(jd float64) (planetPos, earthPos [3]float64) {
+		dt := (jd - jdNow) * 86400
+		return extrapolate(planet, dt), extrapolate(earth, dt)
+	}, currentTime)
+}
+
+// extrapolate projects state's position forward by dt seconds assuming
+// constant velocity, which is accurate enough for the +/-1 day window the
+// rise/transit/set interpolation samples.
+func extrapolate(state State, dt float64) [3]float64 {
+	var pos [3]float64
+	for i := range pos {
+		pos[i] = state.Position[i] + state.Velocity[i]*dt
+	}
+	return pos
+}
+
+// riseTransitSet is the shared implementation behind RiseTransitSet and
+// RiseTransitSetFromState; posAt returns the heliocentric positions (in
+// meters) of the body and of Earth at Julian date jd.
+func riseTransitSet(obs Coord, posAt func(jd float64) (planetPos, earthPos [3]float64), date time.Time) (Times, error) {
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	jd0 := julianDate(midnight)
+
+	ra := make([]float64, 3)
+	dec := make([]float64, 3)
+	for i, jd := range []float64{jd0 - 1, jd0, jd0 + 1} {
+		planetPos, earthPos := posAt(jd)
+		ra[i], dec[i] = geocentricEquatorialFromPositions(planetPos, earthPos)
+	}
+
+	// Unwrap RA across the 0/360 boundary so the quadratic interpolation
+	// below doesn't see a spurious ~360 degree jump between samples.
+	for i := 1; i < 3; i++ {
+		for ra[i]-ra[i-1] > 180 {
+			ra[i] -= 360
+		}
+		for ra[i]-ra[i-1] < -180 {
+			ra[i] += 360
+		}
+	}
+
+	gmst0 := siderealTimeDegrees(jd0)
+
+	latRad := deg2rad(obs.Lat)
+	decRad := deg2rad(dec[1])
+	cosH0 := (math.Sin(deg2rad(standardAltitude)) - math.Sin(latRad)*math.Sin(decRad)) / (math.Cos(latRad) * math.Cos(decRad))
+
+	transitFrac := normalizeFrac((ra[1] - obs.Lon - gmst0) / 360)
+	transitTime := refineTransit(transitFrac, ra, dec, gmst0, obs)
+
+	if cosH0 < -1 || cosH0 > 1 {
+		// Circumpolar (never sets) or never rises; either way there's no
+		// rise/set to report, only a transit.
+		if cosH0 > 1 {
+			return Times{Transit: fracToTime(midnight, transitTime)}, ErrNeverRises
+		}
+		return Times{Transit: fracToTime(midnight, transitTime)}, nil
+	}
+
+	h0 := rad2deg(math.Acos(cosH0))
+	riseFrac := normalizeFrac(transitFrac - h0/360)
+	setFrac := normalizeFrac(transitFrac + h0/360)
+
+	riseTime := refineRiseSet(riseFrac, ra, dec, gmst0, obs)
+	setTime := refineRiseSet(setFrac, ra, dec, gmst0, obs)
+
+	return Times{
+		Rise:    fracToTime(midnight, riseTime),
+		Transit: fracToTime(midnight, transitTime),
+		Set:     fracToTime(midnight, setTime),
+	}, nil
+}
+
+// refineTransit iterates Meeus's correction for the moment the body's hour
+// angle is zero until the correction converges (or maxRefineIterations
+// passes, for safety).
+func refineTransit(m float64, ra, dec []float64, gmst0 float64, obs Coord) float64 {
+	for i := 0; i < maxRefineIterations; i++ {
+		theta := gmst0 + 360.985647*m
+		alphaInterp := interpolate(ra[0], ra[1], ra[2], m)
+		h := wrapSigned(theta + obs.Lon - alphaInterp)
+		deltaM := -h / 360
+		m += deltaM
+		if math.Abs(deltaM) < refineConvergence {
+			break
+		}
+	}
+	return m
+}
+
+// refineRiseSet iterates Meeus's correction for the moment the body's
+// altitude equals standardAltitude until the correction converges (or
+// maxRefineIterations passes, for safety).
+func refineRiseSet(m float64, ra, dec []float64, gmst0 float64, obs Coord) float64 {
+	for i := 0; i < maxRefineIterations; i++ {
+		theta := gmst0 + 360.985647*m
+		alphaInterp := interpolate(ra[0], ra[1], ra[2], m)
+		deltaInterp := interpolate(dec[0], dec[1], dec[2], m)
+
+		h := deg2rad(normalizeAngle(theta + obs.Lon - alphaInterp))
+		latRad := deg2rad(obs.Lat)
+		decRad := deg2rad(deltaInterp)
+
+		altitude := math.Asin(math.Sin(latRad)*math.Sin(decRad) + math.Cos(latRad)*math.Cos(decRad)*math.Cos(h))
+
+		denominator := 360 * math.Cos(decRad) * math.Cos(latRad) * math.Sin(h)
+		if denominator == 0 {
+			break
+		}
+		deltaM := rad2deg(altitude-deg2rad(standardAltitude)) / denominator
+		m += deltaM
+		if math.Abs(deltaM) < refineConvergence {
+			break
+		}
+	}
+	return m
+}
+
+// interpolate evaluates a quadratic through three equally-spaced samples
+// y1, y2, y3 (taken one day apart, centered on y2) at fractional day offset
+// n from y2, per Meeus chapter 3.
+func interpolate(y1, y2, y3, n float64) float64 {
+	a := y2 - y1
+	b := y3 - y2
+	c := b - a
+	return y2 + n/2*(a+b+n*c)
+}
+
+// geocentricEquatorialFromPositions converts a body's heliocentric position
+// pPos into geocentric right ascension and declination, in degrees, as seen
+// from a position ePos on (or near) Earth at the same time.
+func geocentricEquatorialFromPositions(pPos, ePos [3]float64) (ra, dec float64) {
+	geocentric := [3]float64{pPos[0] - ePos[0], pPos[1] - ePos[1], pPos[2] - ePos[2]}
+
+	eps := deg2rad(obliquityJ2000)
+	x := geocentric[0]
+	y := geocentric[1]*math.Cos(eps) - geocentric[2]*math.Sin(eps)
+	z := geocentric[1]*math.Sin(eps) + geocentric[2]*math.Cos(eps)
+
+	r := math.Sqrt(x*x + y*y + z*z)
+	ra = rad2deg(math.Atan2(y, x))
+	if ra < 0 {
+		ra += 360
+	}
+	dec = rad2deg(math.Asin(z / r))
+	return ra, dec
+}
+
+// siderealTimeDegrees returns the Greenwich mean sidereal time at 0h UT on
+// the day containing Julian date jd0 (which must itself be a 0h UT epoch),
+// in degrees (Meeus, equation 12.4).
+func siderealTimeDegrees(jd0 float64) float64 {
+	t := (jd0 - 2451545.0) / 36525
+	gmst := 100.46061837 + 36000.770053608*t + 0.000387933*t*t - t*t*t/38710000
+	return normalizeAngle(gmst)
+}
+
+func julianDate(t time.Time) float64 {
+	t = t.UTC()
+	return float64(t.Unix())/86400.0 + 2440587.5
+}
+
+func fracToTime(midnight time.Time, frac float64) time.Time {
+	frac = normalizeFrac(frac)
+	return midnight.Add(time.Duration(frac * float64(24*time.Hour)))
+}
+
+func normalizeFrac(f float64) float64 {
+	f = math.Mod(f, 1)
+	if f < 0 {
+		f++
+	}
+	return f
+}
+
+func normalizeAngle(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
+
+// wrapSigned normalizes deg into (-180, 180], for hour-angle corrections
+// where we need to know which side of transit we're on.
+func wrapSigned(deg float64) float64 {
+	deg = normalizeAngle(deg)
+	if deg > 180 {
+		deg -= 360
+	}
+	return deg
+}
+
+func deg2rad(d float64) float64 { return d * math.Pi / 180 }
+func rad2deg(r float64) float64 { return r * 180 / math.Pi }
@@ -0,0 +1,93 @@
+package observer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/wizardishungry/nbody/ephemeris"
+)
+
+// TestRefineTransitConverges checks that refineTransit's returned m actually
+// zeroes the hour-angle residual it's iterating on (to within a fraction of
+// a second of time), rather than stopping after a single correction pass
+// that can still be off by minutes.
+func TestRefineTransitConverges(t *testing.T) {
+	ra := []float64{100, 110.5, 121}
+	dec := []float64{10, 10.2, 10.4}
+	const gmst0 = 15.0
+	obs := Coord{Lat: 40, Lon: -74}
+
+	m := refineTransit(0.4, ra, dec, gmst0, obs)
+
+	theta := gmst0 + 360.985647*m
+	alphaInterp := interpolate(ra[0], ra[1], ra[2], m)
+	h := wrapSigned(theta + obs.Lon - alphaInterp)
+
+	const tolDegrees = 360 * refineConvergence * 2 // a couple convergence steps' worth of slack
+	if math.Abs(h) > tolDegrees {
+		t.Errorf("refineTransit: residual hour angle %v degrees exceeds tolerance %v", h, tolDegrees)
+	}
+}
+
+// TestRefineRiseSetConverges is the refineTransit convergence check's
+// counterpart for refineRiseSet: its returned m should make the computed
+// altitude match standardAltitude closely, not just after one pass.
+func TestRefineRiseSetConverges(t *testing.T) {
+	ra := []float64{100, 110.5, 121}
+	dec := []float64{10, 10.2, 10.4}
+	const gmst0 = 15.0
+	obs := Coord{Lat: 40, Lon: -74}
+
+	m := refineRiseSet(0.2, ra, dec, gmst0, obs)
+
+	theta := gmst0 + 360.985647*m
+	alphaInterp := interpolate(ra[0], ra[1], ra[2], m)
+	deltaInterp := interpolate(dec[0], dec[1], dec[2], m)
+	h := deg2rad(normalizeAngle(theta + obs.Lon - alphaInterp))
+	latRad := deg2rad(obs.Lat)
+	decRad := deg2rad(deltaInterp)
+	altitude := math.Asin(math.Sin(latRad)*math.Sin(decRad) + math.Cos(latRad)*math.Cos(decRad)*math.Cos(h))
+
+	const tolDegrees = 0.01
+	if diff := rad2deg(altitude) - standardAltitude; math.Abs(diff) > tolDegrees {
+		t.Errorf("refineRiseSet: altitude %v degrees is %v degrees from standardAltitude %v, want within %v", rad2deg(altitude), diff, standardAltitude, tolDegrees)
+	}
+}
+
+// TestRiseTransitSetFromStateMatchesEphemeris checks that
+// RiseTransitSetFromState, fed a State sampled from the ephemeris at the
+// same instant, reproduces RiseTransitSet's transit time to within a few
+// minutes -- the residual is the kinematic (constant-velocity) approximation
+// RiseTransitSetFromState makes for the +/-1 day samples RiseTransitSet gets
+// from the curved ephemeris directly.
+func TestRiseTransitSetFromStateMatchesEphemeris(t *testing.T) {
+	mars, err := ephemeris.LoadPlanet(ephemeris.Mars)
+	if err != nil {
+		t.Fatalf("LoadPlanet(Mars): %v", err)
+	}
+	earth, err := ephemeris.LoadPlanet(ephemeris.Earth)
+	if err != nil {
+		t.Fatalf("LoadPlanet(Earth): %v", err)
+	}
+	obs := Coord{Lat: 40, Lon: -74}
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	jd := julianDate(now)
+
+	marsPos, marsVel := mars.State(jd)
+	earthPos, earthVel := earth.State(jd)
+
+	want, err := RiseTransitSet(obs, mars, now)
+	if err != nil {
+		t.Fatalf("RiseTransitSet: %v", err)
+	}
+	got, err := RiseTransitSetFromState(obs, State{Position: marsPos, Velocity: marsVel}, State{Position: earthPos, Velocity: earthVel}, now)
+	if err != nil {
+		t.Fatalf("RiseTransitSetFromState: %v", err)
+	}
+
+	const tol = 5 * time.Minute
+	if diff := got.Transit.Sub(want.Transit); diff > tol || diff < -tol {
+		t.Errorf("transit time differs by %v, want within %v (want=%v got=%v)", diff, tol, want.Transit, got.Transit)
+	}
+}